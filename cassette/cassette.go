@@ -0,0 +1,149 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cassette records and replays HTTP request/response pairs as a newline-delimited JSON
+// file, turning httpr into a deterministic mock server for the `record` and `replay` subcommands.
+package cassette
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"os"
+	"sync"
+)
+
+// Entry is a single recorded request/response exchange
+type Entry struct {
+	Method          string      `json:"method"`
+	Path            string      `json:"path"`
+	Headers         http.Header `json:"headers,omitempty"`
+	Body            string      `json:"body,omitempty"`
+	ResponseStatus  int         `json:"responseStatus"`
+	ResponseHeaders http.Header `json:"responseHeaders,omitempty"`
+	ResponseBody    string      `json:"responseBody,omitempty"`
+}
+
+// Cassette is an in-memory, optionally file-backed sequence of Entry records
+type Cassette struct {
+	mu      sync.Mutex
+	entries []Entry
+	file    *os.File
+}
+
+// Create opens path for writing and returns a Cassette that appends every Record call to it as
+// newline-delimited JSON, truncating any existing content
+func Create(path string) (*Cassette, error) {
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Cassette{file: file}, nil
+}
+
+// Load reads a newline-delimited JSON cassette file into memory for replay
+func Load(path string) (*Cassette, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	c := &Cassette{}
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var entry Entry
+		if err := json.Unmarshal(line, &entry); err != nil {
+			return nil, err
+		}
+
+		c.entries = append(c.entries, entry)
+	}
+
+	return c, scanner.Err()
+}
+
+// Record appends e to the cassette file as a single JSON line
+func (c *Cassette) Record(e Entry) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+
+	line = append(line, '\n')
+	_, err = c.file.Write(line)
+	return err
+}
+
+// Match returns the first recorded Entry matching method and path, and, when matchHeaders or
+// matchBody are set, whose headers or body also match the request exactly
+func (c *Cassette) Match(method, path string, headers http.Header, body []byte, matchHeaders, matchBody bool) (Entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, entry := range c.entries {
+		if entry.Method != method || entry.Path != path {
+			continue
+		}
+
+		if matchHeaders && !headersEqual(entry.Headers, headers) {
+			continue
+		}
+
+		if matchBody && entry.Body != string(body) {
+			continue
+		}
+
+		return entry, true
+	}
+
+	return Entry{}, false
+}
+
+// Close closes the underlying cassette file, if one is open for recording
+func (c *Cassette) Close() error {
+	if c.file == nil {
+		return nil
+	}
+	return c.file.Close()
+}
+
+func headersEqual(a, b http.Header) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for key, aValues := range a {
+		bValues := b[key]
+		if len(aValues) != len(bValues) {
+			return false
+		}
+		for i := range aValues {
+			if aValues[i] != bValues[i] {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,122 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cassette
+
+import (
+	"net/http"
+	"testing"
+)
+
+func newTestCassette(entries ...Entry) *Cassette {
+	return &Cassette{entries: entries}
+}
+
+func TestCassetteMatchByMethodAndPath(t *testing.T) {
+	c := newTestCassette(Entry{Method: "GET", Path: "/foo", ResponseStatus: http.StatusOK})
+
+	entry, ok := c.Match("GET", "/foo", nil, nil, false, false)
+	if !ok {
+		t.Fatal("expected a match on method and path")
+	}
+	if entry.ResponseStatus != http.StatusOK {
+		t.Fatalf("ResponseStatus = %d, want %d", entry.ResponseStatus, http.StatusOK)
+	}
+
+	if _, ok := c.Match("GET", "/bar", nil, nil, false, false); ok {
+		t.Fatal("expected no match for a different path")
+	}
+	if _, ok := c.Match("POST", "/foo", nil, nil, false, false); ok {
+		t.Fatal("expected no match for a different method")
+	}
+}
+
+func TestCassetteMatchHeaders(t *testing.T) {
+	c := newTestCassette(Entry{
+		Method:  "GET",
+		Path:    "/foo",
+		Headers: http.Header{"X-Api-Key": []string{"secret"}},
+	})
+
+	if _, ok := c.Match("GET", "/foo", http.Header{"X-Api-Key": []string{"wrong"}}, nil, true, false); ok {
+		t.Fatal("expected no match when matchHeaders is set and headers differ")
+	}
+
+	entry, ok := c.Match("GET", "/foo", http.Header{"X-Api-Key": []string{"secret"}}, nil, true, false)
+	if !ok {
+		t.Fatal("expected a match when matchHeaders is set and headers are identical")
+	}
+	if entry.Path != "/foo" {
+		t.Fatalf("Path = %q, want /foo", entry.Path)
+	}
+
+	// With matchHeaders unset, a header mismatch is ignored.
+	if _, ok := c.Match("GET", "/foo", http.Header{"X-Api-Key": []string{"wrong"}}, nil, false, false); !ok {
+		t.Fatal("expected a match when matchHeaders is unset, regardless of headers")
+	}
+}
+
+func TestCassetteMatchBody(t *testing.T) {
+	c := newTestCassette(Entry{Method: "POST", Path: "/foo", Body: "hello"})
+
+	if _, ok := c.Match("POST", "/foo", nil, []byte("goodbye"), false, true); ok {
+		t.Fatal("expected no match when matchBody is set and bodies differ")
+	}
+
+	if _, ok := c.Match("POST", "/foo", nil, []byte("hello"), false, true); !ok {
+		t.Fatal("expected a match when matchBody is set and bodies are identical")
+	}
+
+	// With matchBody unset, a body mismatch is ignored.
+	if _, ok := c.Match("POST", "/foo", nil, []byte("goodbye"), false, false); !ok {
+		t.Fatal("expected a match when matchBody is unset, regardless of body")
+	}
+}
+
+func TestCassetteMatchReturnsFirstMatch(t *testing.T) {
+	c := newTestCassette(
+		Entry{Method: "GET", Path: "/foo", ResponseStatus: http.StatusOK},
+		Entry{Method: "GET", Path: "/foo", ResponseStatus: http.StatusTeapot},
+	)
+
+	entry, ok := c.Match("GET", "/foo", nil, nil, false, false)
+	if !ok {
+		t.Fatal("expected a match")
+	}
+	if entry.ResponseStatus != http.StatusOK {
+		t.Fatalf("ResponseStatus = %d, want the first recorded entry's %d", entry.ResponseStatus, http.StatusOK)
+	}
+}
+
+func TestHeadersEqual(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b http.Header
+		want bool
+	}{
+		{name: "both nil", a: nil, b: nil, want: true},
+		{name: "identical single value", a: http.Header{"X": {"1"}}, b: http.Header{"X": {"1"}}, want: true},
+		{name: "different value", a: http.Header{"X": {"1"}}, b: http.Header{"X": {"2"}}, want: false},
+		{name: "different key count", a: http.Header{"X": {"1"}, "Y": {"2"}}, b: http.Header{"X": {"1"}}, want: false},
+		{name: "different multi-value order", a: http.Header{"X": {"1", "2"}}, b: http.Header{"X": {"2", "1"}}, want: false},
+		{name: "missing key", a: http.Header{"X": {"1"}}, b: http.Header{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := headersEqual(tt.a, tt.b); got != tt.want {
+				t.Fatalf("headersEqual(%v, %v) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
@@ -14,11 +14,14 @@
 package cmd
 
 import (
+	"log"
 	"net/http"
 
+	"github.com/netbucket/httpr/context"
 	"github.com/netbucket/httpr/handlers"
+	"github.com/netbucket/httpr/handlers/middleware"
+	"github.com/netbucket/httpr/handlers/sink"
 	"github.com/spf13/cobra"
-	"os"
 )
 
 // logCmd represents the log command
@@ -34,9 +37,46 @@ func init() {
 	RootCmd.AddCommand(logCmd)
 
 	logCmd.Flags().BoolP("json", "j", false, "Log HTTP requests in JSON format")
+	logCmd.Flags().String("log-file", "", "Path to a file to log requests to, in addition to the standard output")
+	logCmd.Flags().Int64("log-max-size", 0, "Rotate the log file once it exceeds this many bytes (0 disables size-based rotation)")
+	logCmd.Flags().Duration("log-max-age", 0, "Rotate the log file once it is older than this duration (0 disables age-based rotation)")
+	logCmd.Flags().Int("log-max-backups", 0, "Maximum number of rotated log files to keep (0 keeps them all)")
 }
 
 func executeLog(cmd *cobra.Command, args []string) {
-	http.Handle("/", handlers.RawRequestLoggingHandler(os.Stdout, nil))
+	ctx := context.Instance()
+
+	ctx.Use(middleware.RequestID)
+
+	out, err := logSinkFromFlags(cmd)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx.Out = out
+	ctx.Closers = append(ctx.Closers, out)
+
+	ctx.Mux = http.NewServeMux()
+	ctx.Mux.Handle("/", handlers.RawRequestLoggingHandler(ctx.Out, nil))
+
 	startServer()
 }
+
+// logSinkFromFlags builds the Sink executeLog writes request logs to: the standard output alone,
+// or the standard output fanned out to a rotating log file when --log-file is set
+func logSinkFromFlags(cmd *cobra.Command) (sink.Sink, error) {
+	logFile, _ := cmd.Flags().GetString("log-file")
+	if logFile == "" {
+		return sink.Stdout(), nil
+	}
+
+	maxSize, _ := cmd.Flags().GetInt64("log-max-size")
+	maxAge, _ := cmd.Flags().GetDuration("log-max-age")
+	maxBackups, _ := cmd.Flags().GetInt("log-max-backups")
+
+	fileSink, err := sink.NewFileSink(logFile, maxSize, maxAge, maxBackups)
+	if err != nil {
+		return nil, err
+	}
+
+	return sink.Multi(sink.Stdout(), fileSink), nil
+}
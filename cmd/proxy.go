@@ -0,0 +1,110 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"os"
+
+	"github.com/netbucket/httpr/context"
+	"github.com/spf13/cobra"
+)
+
+// proxyCmd represents the proxy command
+var proxyCmd = &cobra.Command{
+	Use:   "proxy",
+	Short: "Forward incoming requests to an upstream, retrying on transient failures",
+	Long: `Forward each incoming request to --upstream, retrying the outbound call with exponential
+backoff and jitter when it fails to connect or comes back with a retryable status code. Combine with
+--code/--failure-* on the inbound side to short-circuit requests with a synthetic failure before they
+ever reach the upstream, composing a "flaky client -> retry -> flaky server" scenario in one process.`,
+	Run: executeProxy,
+}
+
+func init() {
+	RootCmd.AddCommand(proxyCmd)
+
+	proxyCmd.Flags().String("upstream", "", "URL of the upstream to forward requests to")
+	proxyCmd.Flags().Duration("retry-min", defaultRetryMin, "Minimum backoff wait between retry attempts")
+	proxyCmd.Flags().Duration("retry-max", defaultRetryMax, "Maximum backoff wait between retry attempts")
+	proxyCmd.Flags().Int("retry-attempts", defaultRetryAttempts, "Maximum number of attempts against the upstream, including the first")
+	proxyCmd.Flags().IntSlice("retry-status", defaultRetryStatusCodes, "Upstream response status codes that trigger a retry")
+
+	proxyCmd.MarkFlagRequired("upstream")
+}
+
+func executeProxy(cmd *cobra.Command, args []string) {
+	ctx := context.Instance()
+
+	upstream, _ := cmd.Flags().GetString("upstream")
+	retryMin, _ := cmd.Flags().GetDuration("retry-min")
+	retryMax, _ := cmd.Flags().GetDuration("retry-max")
+	retryAttempts, _ := cmd.Flags().GetInt("retry-attempts")
+	retryStatus, _ := cmd.Flags().GetIntSlice("retry-status")
+
+	upstreamURL, err := url.Parse(upstream)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var logOut io.Writer = ctx.Out
+	if logOut == nil {
+		logOut = os.Stdout
+	}
+
+	proxy := httputil.NewSingleHostReverseProxy(upstreamURL)
+	proxy.Transport = newRetryableTransport(http.DefaultTransport, retryMin, retryMax, retryAttempts, retryStatus, logOut)
+
+	ctx.Mux = http.NewServeMux()
+	ctx.Mux.Handle("/", proxyingHandler(ctx, proxy))
+
+	startServer()
+}
+
+// proxyingHandler short-circuits requests through Context.FailureMode before forwarding the
+// remainder to proxy
+func proxyingHandler(ctx *context.Context, proxy *httputil.ReverseProxy) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if ctx.FailureMode.Enabled {
+			if status, failed := ctx.SimulateFailure(); failed {
+				w.WriteHeader(status)
+				return
+			}
+		}
+
+		// httputil.ReverseProxy clones the inbound request without setting GetBody, so a retry
+		// attempt would resend an already-drained Body. Buffer it once here so retryableTransport
+		// can rewind it on every attempt.
+		if r.Body != nil && r.Body != http.NoBody {
+			body, err := io.ReadAll(r.Body)
+			r.Body.Close()
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+
+			r.Body = io.NopCloser(bytes.NewReader(body))
+			r.GetBody = func() (io.ReadCloser, error) {
+				return io.NopCloser(bytes.NewReader(body)), nil
+			}
+		}
+
+		proxy.ServeHTTP(w, r)
+	})
+}
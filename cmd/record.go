@@ -0,0 +1,92 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/netbucket/httpr/cassette"
+	"github.com/netbucket/httpr/context"
+	"github.com/spf13/cobra"
+)
+
+// recordCmd represents the record command
+var recordCmd = &cobra.Command{
+	Use:   "record",
+	Short: "Record the incoming HTTP requests and the responses httpr chose into a cassette",
+	Long: `Record the incoming HTTP requests (method, path, headers, body) together with the response
+httpr chose into a newline-delimited JSON cassette file. The resulting cassette can later be fed to
+the replay command to turn httpr into a deterministic mock server.`,
+	Run: executeRecord,
+}
+
+func init() {
+	RootCmd.AddCommand(recordCmd)
+
+	recordCmd.Flags().String("cassette", "httpr.cassette.ndjson", "Path to the cassette file to record requests into")
+}
+
+func executeRecord(cmd *cobra.Command, args []string) {
+	ctx := context.Instance()
+
+	cassettePath, _ := cmd.Flags().GetString("cassette")
+
+	cas, err := cassette.Create(cassettePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx.Closers = append(ctx.Closers, cas)
+
+	ctx.Mux = http.NewServeMux()
+	ctx.Mux.Handle("/", recordingHandler(ctx, cas))
+
+	startServer()
+}
+
+// recordingHandler replays the server's normal response behavior while capturing every exchange
+// into cas
+func recordingHandler(ctx *context.Context, cas *cassette.Cassette) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		if ctx.Delay > 0 {
+			time.Sleep(time.Duration(ctx.Delay) * time.Millisecond)
+		}
+
+		status, _ := ctx.SimulateFailure()
+
+		responseBody := []byte{}
+		if ctx.Echo {
+			responseBody = body
+		}
+
+		w.WriteHeader(status)
+		w.Write(responseBody)
+
+		if err := cas.Record(cassette.Entry{
+			Method:          r.Method,
+			Path:            r.URL.Path,
+			Headers:         r.Header,
+			Body:            string(body),
+			ResponseStatus:  status,
+			ResponseHeaders: w.Header(),
+			ResponseBody:    string(responseBody),
+		}); err != nil {
+			log.Println(err)
+		}
+	})
+}
@@ -0,0 +1,87 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"io"
+	"log"
+	"net/http"
+
+	"github.com/netbucket/httpr/cassette"
+	"github.com/netbucket/httpr/context"
+	"github.com/spf13/cobra"
+)
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Replay canned responses from a recorded cassette",
+	Long: `Replay responses recorded by the record command. Each incoming request is matched against
+the cassette by method and path, optionally also by headers and/or body, and the recorded status,
+headers and body are returned. Requests that don't match any recorded entry fall back to the usual
+--code/--failure-* response behavior.`,
+	Run: executeReplay,
+}
+
+func init() {
+	RootCmd.AddCommand(replayCmd)
+
+	replayCmd.Flags().String("cassette", "", "Path to the cassette file to replay responses from")
+	replayCmd.Flags().Bool("match-headers", false, "Also require an exact header match before replaying a recorded response")
+	replayCmd.Flags().Bool("match-body", false, "Also require an exact body match before replaying a recorded response")
+
+	replayCmd.MarkFlagRequired("cassette")
+}
+
+func executeReplay(cmd *cobra.Command, args []string) {
+	ctx := context.Instance()
+
+	cassettePath, _ := cmd.Flags().GetString("cassette")
+	matchHeaders, _ := cmd.Flags().GetBool("match-headers")
+	matchBody, _ := cmd.Flags().GetBool("match-body")
+
+	cas, err := cassette.Load(cassettePath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx.Mux = http.NewServeMux()
+	ctx.Mux.Handle("/", replayingHandler(ctx, cas, matchHeaders, matchBody))
+
+	startServer()
+}
+
+// replayingHandler serves recorded responses from cas, falling back to the Context's usual
+// HttpCode/FailureMode behavior when a request matches no recorded entry
+func replayingHandler(ctx *context.Context, cas *cassette.Cassette, matchHeaders, matchBody bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+
+		entry, ok := cas.Match(r.Method, r.URL.Path, r.Header, body, matchHeaders, matchBody)
+		if !ok {
+			status, _ := ctx.SimulateFailure()
+			w.WriteHeader(status)
+			return
+		}
+
+		for key, values := range entry.ResponseHeaders {
+			for _, value := range values {
+				w.Header().Add(key, value)
+			}
+		}
+
+		w.WriteHeader(entry.ResponseStatus)
+		w.Write([]byte(entry.ResponseBody))
+	})
+}
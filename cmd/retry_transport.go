@@ -0,0 +1,142 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Defaults for the proxy command's retry policy, modeled on go-retryablehttp
+const (
+	defaultRetryMin      = 500 * time.Millisecond
+	defaultRetryMax      = 30 * time.Second
+	defaultRetryAttempts = 4
+)
+
+var defaultRetryStatusCodes = []int{http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout}
+
+// retryableTransport wraps an http.RoundTripper with exponential backoff-and-jitter retries on
+// connection errors and on a configurable set of upstream status codes
+type retryableTransport struct {
+	transport   http.RoundTripper
+	minWait     time.Duration
+	maxWait     time.Duration
+	maxAttempts int
+	retryCodes  map[int]bool
+	out         io.Writer
+}
+
+// newRetryableTransport builds a retryableTransport that sends each request through base up to
+// maxAttempts times in all, the first attempt included
+func newRetryableTransport(base http.RoundTripper, minWait, maxWait time.Duration, maxAttempts int, retryStatusCodes []int, out io.Writer) *retryableTransport {
+	codes := make(map[int]bool, len(retryStatusCodes))
+	for _, code := range retryStatusCodes {
+		codes[code] = true
+	}
+
+	return &retryableTransport{
+		transport:   base,
+		minWait:     minWait,
+		maxWait:     maxWait,
+		maxAttempts: maxAttempts,
+		retryCodes:  codes,
+		out:         out,
+	}
+}
+
+// RoundTrip implements http.RoundTripper, sending req up to maxAttempts times in all
+func (t *retryableTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+
+	for attempt := 1; attempt <= t.maxAttempts; attempt++ {
+		if attempt > 1 && req.GetBody != nil {
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return nil, bodyErr
+			}
+			req.Body = body
+		}
+
+		start := time.Now()
+		resp, err = t.transport.RoundTrip(req)
+		latency := time.Since(start)
+
+		t.logAttempt(req, attempt, latency, resp, err)
+
+		retryable := err != nil || t.retryCodes[resp.StatusCode]
+		if !retryable || attempt == t.maxAttempts {
+			return resp, err
+		}
+
+		wait := t.backoff(attempt)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+				wait = retryAfter
+			}
+			resp.Body.Close()
+		}
+
+		time.Sleep(wait)
+	}
+
+	return resp, err
+}
+
+// backoff computes an exponential wait for attempt, capped at maxWait and jittered by up to half
+// of the computed wait to avoid thundering-herd retries
+func (t *retryableTransport) backoff(attempt int) time.Duration {
+	wait := t.minWait << uint(attempt-1)
+	if wait > t.maxWait || wait <= 0 {
+		wait = t.maxWait
+	}
+
+	jitter := time.Duration(rand.Int63n(int64(wait)/2 + 1))
+
+	return wait/2 + jitter
+}
+
+func (t *retryableTransport) logAttempt(req *http.Request, attempt int, latency time.Duration, resp *http.Response, err error) {
+	outcome := "error"
+	if err != nil {
+		outcome = err.Error()
+	} else if resp != nil {
+		outcome = strconv.Itoa(resp.StatusCode)
+	}
+
+	fmt.Fprintf(t.out, "proxy attempt=%d method=%s path=%s latency=%s outcome=%s\n", attempt, req.Method, req.URL.Path, latency, outcome)
+}
+
+// parseRetryAfter parses an HTTP Retry-After header value, either as a number of seconds or as an
+// HTTP date, returning ok=false when value is empty or unparseable
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	if when, err := http.ParseTime(value); err == nil {
+		return time.Until(when), true
+	}
+
+	return 0, false
+}
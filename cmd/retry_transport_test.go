@@ -0,0 +1,97 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryableTransportBackoffStaysWithinBounds(t *testing.T) {
+	transport := newRetryableTransport(nil, 500*time.Millisecond, 30*time.Second, defaultRetryAttempts, nil, nil)
+
+	// attempt-1 grows well past the 64 bit shift width exercised by backoff's left shift, covering
+	// the range where minWait<<uint(attempt-1) overflows or truncates to zero/negative.
+	for attempt := 1; attempt <= 100; attempt++ {
+		wait := transport.backoff(attempt)
+
+		if wait < 0 {
+			t.Fatalf("attempt %d: backoff returned a negative wait: %s", attempt, wait)
+		}
+		if wait > transport.maxWait {
+			t.Fatalf("attempt %d: backoff returned %s, want <= maxWait %s", attempt, wait, transport.maxWait)
+		}
+	}
+}
+
+func TestRetryableTransportBackoffGrowsThenCaps(t *testing.T) {
+	transport := newRetryableTransport(nil, 100*time.Millisecond, time.Second, defaultRetryAttempts, nil, nil)
+
+	first := transport.backoff(1)
+	if first > transport.maxWait {
+		t.Fatalf("attempt 1: backoff returned %s, want <= maxWait %s", first, transport.maxWait)
+	}
+	if first > transport.minWait {
+		t.Fatalf("attempt 1: backoff returned %s, want <= minWait %s", first, transport.minWait)
+	}
+
+	// By attempt 10, minWait<<9 comfortably exceeds maxWait, so backoff must have capped.
+	capped := transport.backoff(10)
+	if capped > transport.maxWait {
+		t.Fatalf("attempt 10: backoff returned %s, want <= maxWait %s", capped, transport.maxWait)
+	}
+	// Jitter takes off at most half of the capped wait.
+	if capped < transport.maxWait/2 {
+		t.Fatalf("attempt 10: backoff returned %s, want >= maxWait/2 %s", capped, transport.maxWait/2)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		wantOK  bool
+		wantDur time.Duration
+	}{
+		{name: "empty", value: "", wantOK: false},
+		{name: "unparseable", value: "not-a-value", wantOK: false},
+		{name: "seconds", value: "120", wantOK: true, wantDur: 120 * time.Second},
+		{name: "zero seconds", value: "0", wantOK: true, wantDur: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(tt.value)
+			if ok != tt.wantOK {
+				t.Fatalf("parseRetryAfter(%q) ok = %v, want %v", tt.value, ok, tt.wantOK)
+			}
+			if ok && got != tt.wantDur {
+				t.Fatalf("parseRetryAfter(%q) = %s, want %s", tt.value, got, tt.wantDur)
+			}
+		})
+	}
+
+	t.Run("http date", func(t *testing.T) {
+		future := time.Now().Add(2 * time.Minute).UTC()
+		got, ok := parseRetryAfter(future.Format(http.TimeFormat))
+		if !ok {
+			t.Fatalf("parseRetryAfter(%q) ok = false, want true", future.Format(http.TimeFormat))
+		}
+		// Allow a small tolerance since parseRetryAfter measures against time.Now() internally.
+		if got < time.Minute || got > 3*time.Minute {
+			t.Fatalf("parseRetryAfter(http date) = %s, want ~2m", got)
+		}
+	})
+}
@@ -0,0 +1,81 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"crypto/tls"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/netbucket/httpr/context"
+	"github.com/spf13/cobra"
+)
+
+// RootCmd is the base command that every httpr subcommand attaches to
+var RootCmd = &cobra.Command{
+	Use:   "httpr",
+	Short: "A simple, configurable HTTP test server",
+	Long: `httpr is a small HTTP server for testing and simulating the behavior of HTTP clients and services.
+Run one of its subcommands to pick a mode of operation.`,
+}
+
+// clientAuthModes maps the --client-auth flag values to the tls.ClientAuthType they select
+var clientAuthModes = map[string]tls.ClientAuthType{
+	"none":               tls.NoClientCert,
+	"request":            tls.RequestClientCert,
+	"require-and-verify": tls.RequireAndVerifyClientCert,
+}
+
+func init() {
+	RootCmd.PersistentFlags().String("tls-cert", "", "Path to the TLS certificate file, enables HTTPS when set together with --tls-key")
+	RootCmd.PersistentFlags().String("tls-key", "", "Path to the TLS private key file, enables HTTPS when set together with --tls-cert")
+	RootCmd.PersistentFlags().String("client-ca", "", "Path to a PEM encoded CA bundle used to verify client certificates")
+	RootCmd.PersistentFlags().String("client-auth", "none", "Client certificate authentication mode: none, request or require-and-verify")
+	RootCmd.PersistentFlags().Duration("shutdown-timeout", 5*time.Second, "How long to wait for in-flight requests to drain on shutdown")
+
+	cobra.OnInitialize(initTLSContext)
+}
+
+// initTLSContext copies the TLS and shutdown related persistent flags onto the shared Context ahead
+// of every command run
+func initTLSContext() {
+	ctx := context.Instance()
+
+	ctx.TLSCertFile, _ = RootCmd.PersistentFlags().GetString("tls-cert")
+	ctx.TLSKeyFile, _ = RootCmd.PersistentFlags().GetString("tls-key")
+	ctx.ClientCAFile, _ = RootCmd.PersistentFlags().GetString("client-ca")
+	ctx.ShutdownTimeout, _ = RootCmd.PersistentFlags().GetDuration("shutdown-timeout")
+
+	mode, _ := RootCmd.PersistentFlags().GetString("client-auth")
+	clientAuth, ok := clientAuthModes[mode]
+	if !ok {
+		fmt.Printf("unknown --client-auth mode %q, defaulting to none\n", mode)
+		clientAuth = tls.NoClientCert
+	}
+	ctx.ClientAuth = clientAuth
+}
+
+// Execute adds all child commands to the root command and runs it
+func Execute() {
+	if err := RootCmd.Execute(); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// startServer starts the HTTP server using the settings accumulated on the shared Context
+func startServer() {
+	context.Instance().StartServer()
+}
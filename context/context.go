@@ -14,6 +14,10 @@
 package context
 
 import (
+	gocontext "context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
@@ -21,19 +25,44 @@ import (
 	"os/signal"
 	"sync"
 	"syscall"
+	"time"
+
+	"github.com/netbucket/httpr/handlers/middleware"
+	"github.com/netbucket/httpr/handlers/sink"
 )
 
+// defaultShutdownTimeout bounds how long StartServer waits for in-flight requests to drain
+// when ShutdownTimeout is left unset
+const defaultShutdownTimeout = 5 * time.Second
+
 // Context type holds the desired execution profile for a command
 type Context struct {
-	Mutex         *sync.Mutex
-	HttpService   string
-	Out           io.Writer
-	LogJSON       bool
-	LogPrettyJSON bool
-	Echo          bool
-	HttpCode      int
-	Delay         int
-	FailureMode   FailureSimulation
+	Mutex           *sync.Mutex
+	HttpService     string
+	Out             sink.Sink
+	LogJSON         bool
+	LogPrettyJSON   bool
+	Echo            bool
+	HttpCode        int
+	Delay           int
+	FailureMode     FailureSimulation
+	TLSCertFile     string
+	TLSKeyFile      string
+	ClientCAFile    string
+	ClientAuth      tls.ClientAuthType
+	Mux             *http.ServeMux
+	ShutdownTimeout time.Duration
+	Closers         []io.Closer
+	Middlewares     middleware.Chain
+
+	server    *http.Server
+	closeCh   chan struct{}
+	closeOnce sync.Once
+}
+
+// Use appends middlewares to the chain that wraps the Mux handler when the server starts
+func (ctx *Context) Use(middlewares ...middleware.Middleware) {
+	ctx.Middlewares = append(ctx.Middlewares, middlewares...)
 }
 
 // FailureSimulation desribes the intended behavior of the transient failure mode in httpr
@@ -53,31 +82,114 @@ var once sync.Once
 
 func Instance() *Context {
 	once.Do(func() {
-		singleton = &Context{Mutex: &sync.Mutex{}, FailureMode: FailureSimulation{Enabled: false}}
+		singleton = &Context{Mutex: &sync.Mutex{}, FailureMode: FailureSimulation{Enabled: false}, HttpCode: http.StatusOK}
 	})
 	return singleton
 }
 
-// Start the HTTP server
+// Start the HTTP server, serving plain HTTP unless a TLS certificate and key have been configured.
+// It blocks until the process receives SIGINT/SIGTERM or Close is called, then drains in-flight
+// requests before returning.
 func (ctx *Context) StartServer() {
-	go log.Fatal(http.ListenAndServe(ctx.HttpService, nil))
+	// Reset the per-run shutdown state so a Context can be started and stopped more than once,
+	// e.g. across successive tests that embed httpr.
+	ctx.closeOnce = sync.Once{}
+	ctx.closeCh = make(chan struct{})
+
+	if ctx.Mux == nil {
+		ctx.Mux = http.NewServeMux()
+	}
+
+	var handler http.Handler = ctx.Mux
+	if len(ctx.Middlewares) > 0 {
+		handler = ctx.Middlewares.Then(handler)
+	}
+
+	ctx.server = &http.Server{
+		Addr:    ctx.HttpService,
+		Handler: handler,
+	}
+
+	serveErr := make(chan error, 1)
 
-	ch := make(chan os.Signal)
+	go func() {
+		var err error
+
+		if ctx.TLSCertFile != "" && ctx.TLSKeyFile != "" {
+			tlsConfig, tlsErr := ctx.buildTLSConfig()
+			if tlsErr != nil {
+				serveErr <- tlsErr
+				return
+			}
+
+			ctx.server.TLSConfig = tlsConfig
+			err = ctx.server.ListenAndServeTLS(ctx.TLSCertFile, ctx.TLSKeyFile)
+		} else {
+			err = ctx.server.ListenAndServe()
+		}
+
+		if err != nil && err != http.ErrServerClosed {
+			serveErr <- err
+		}
+	}()
+
+	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
-	<-ch
+
+	select {
+	case <-ch:
+	case <-ctx.closeCh:
+		return
+	case err := <-serveErr:
+		log.Fatal(err)
+	}
+
+	if err := ctx.Close(); err != nil {
+		log.Println(err)
+	}
 }
 
-// Execute a failure simulation and return an HTTP code representing the outcome
-func (ctx *Context) SimulateFailure() int {
+// buildTLSConfig assembles the server TLS configuration, optionally requiring and verifying client
+// certificates against ClientCAFile when ClientAuth calls for it
+func (ctx *Context) buildTLSConfig() (*tls.Config, error) {
+	tlsConfig := &tls.Config{ClientAuth: ctx.ClientAuth}
+
+	if ctx.ClientCAFile == "" {
+		return tlsConfig, nil
+	}
+
+	caCert, err := os.ReadFile(ctx.ClientCAFile)
+	if err != nil {
+		return nil, err
+	}
+
+	caCertPool := x509.NewCertPool()
+	if !caCertPool.AppendCertsFromPEM(caCert) {
+		return nil, fmt.Errorf("failed to parse client CA certificate %s", ctx.ClientCAFile)
+	}
+
+	tlsConfig.ClientCAs = caCertPool
+
+	return tlsConfig, nil
+}
+
+// SimulateFailure executes a failure simulation and returns an HTTP code representing the outcome,
+// along with whether this call actually took the failure branch. Callers that need to distinguish a
+// synthetic failure from a synthetic success must use that second value rather than comparing the
+// outcome to HttpCode, since SuccessCode is independently configurable and is not guaranteed to equal
+// HttpCode.
+func (ctx *Context) SimulateFailure() (int, bool) {
 	ctx.Mutex.Lock()
 
 	defer ctx.Mutex.Unlock()
 
 	var outcome int = ctx.HttpCode
+	var failed bool
 
 	if ctx.FailureMode.Enabled {
 		if ctx.FailureMode.FailureIterationCount < ctx.FailureMode.FailureCount {
 			outcome = ctx.FailureMode.FailureCode
+			failed = true
 
 			ctx.FailureMode.FailureIterationCount++
 
@@ -108,10 +220,38 @@ func (ctx *Context) SimulateFailure() int {
 		}
 	}
 
-	return outcome
+	return outcome, failed
 }
 
-// Close the context
-func (ctx *Context) Close() {
-	// noop for now
+// Close shuts the running server down, giving in-flight requests up to ShutdownTimeout to complete,
+// and closes any sinks registered in Closers (e.g. files opened for JSON logging). It is safe to call
+// more than once and safe to call concurrently with StartServer.
+func (ctx *Context) Close() error {
+	var shutdownErr error
+
+	ctx.closeOnce.Do(func() {
+		if ctx.server != nil {
+			timeout := ctx.ShutdownTimeout
+			if timeout <= 0 {
+				timeout = defaultShutdownTimeout
+			}
+
+			shutdownCtx, cancel := gocontext.WithTimeout(gocontext.Background(), timeout)
+			defer cancel()
+
+			shutdownErr = ctx.server.Shutdown(shutdownCtx)
+		}
+
+		for _, closer := range ctx.Closers {
+			if err := closer.Close(); err != nil && shutdownErr == nil {
+				shutdownErr = err
+			}
+		}
+
+		if ctx.closeCh != nil {
+			close(ctx.closeCh)
+		}
+	})
+
+	return shutdownErr
 }
@@ -0,0 +1,47 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package handlers holds the http.Handler implementations the httpr subcommands wire onto their mux.
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httputil"
+
+	"github.com/netbucket/httpr/handlers/middleware"
+)
+
+// LoggingOptions configures RawRequestLoggingHandler. A nil *LoggingOptions uses the defaults.
+type LoggingOptions struct{}
+
+// RawRequestLoggingHandler dumps each incoming request, verbatim, to out. When the RequestID
+// middleware has run, the resolved request ID is prefixed to the dumped line so log entries can be
+// correlated with the caller's own traces.
+func RawRequestLoggingHandler(out io.Writer, opts *LoggingOptions) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		dump, err := httputil.DumpRequest(r, true)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if requestID := middleware.RequestIDFromContext(r.Context()); requestID != "" {
+			fmt.Fprintf(out, "[%s] ", requestID)
+		}
+		out.Write(dump)
+
+		w.WriteHeader(http.StatusOK)
+	})
+}
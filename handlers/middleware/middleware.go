@@ -0,0 +1,38 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package middleware provides a small http.Handler chain so cross-cutting concerns (request IDs,
+// access logs, etc.) can be composed once on the Context instead of being bolted onto the mux
+// inside each cmd/*.go file.
+package middleware
+
+import "net/http"
+
+// Middleware wraps an http.Handler to add behavior before and/or after it runs
+type Middleware func(http.Handler) http.Handler
+
+// Chain is an ordered list of Middleware applied outermost-first
+type Chain []Middleware
+
+// New builds a Chain from the given middlewares, applied in the order passed
+func New(middlewares ...Middleware) Chain {
+	return Chain(middlewares)
+}
+
+// Then wraps h with every Middleware in the chain, outermost first, and returns the composed handler
+func (c Chain) Then(h http.Handler) http.Handler {
+	for i := len(c) - 1; i >= 0; i-- {
+		h = c[i](h)
+	}
+	return h
+}
@@ -0,0 +1,60 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package middleware
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+)
+
+// RequestIDHeader is the header httpr reads an inbound request ID from and echoes it back on
+const RequestIDHeader = "X-Request-Id"
+
+// requestIDKey is the context.Context key RequestID stores the resolved request ID under
+type requestIDKey struct{}
+
+// RequestID reads X-Request-Id from the incoming request, generating one if it is absent, echoes it
+// back on the response and injects it into the request context so downstream handlers and loggers
+// can correlate a client's own traces with httpr's emitted log lines
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if id == "" {
+			id = newRequestID()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := context.WithValue(r.Context(), requestIDKey{}, id)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// RequestIDFromContext returns the request ID injected by RequestID, or the empty string if none
+// is present (e.g. the middleware was never applied)
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// newRequestID generates a random 16 byte identifier, hex encoded
+func newRequestID() string {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "00000000000000000000000000000000"
+	}
+	return fmt.Sprintf("%x", buf)
+}
@@ -0,0 +1,149 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sink
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+)
+
+// FileSink writes to path, rotating it to a timestamped backup once it exceeds MaxSize bytes or
+// MaxAge in age, and pruning backups beyond MaxBackups
+type FileSink struct {
+	Path       string
+	MaxSize    int64
+	MaxAge     time.Duration
+	MaxBackups int
+
+	mu       sync.Mutex
+	file     *os.File
+	size     int64
+	openedAt time.Time
+}
+
+// NewFileSink opens (or creates) path for appending, ready to rotate according to maxSize,
+// maxAge and maxBackups. A zero maxSize or maxAge disables that rotation trigger.
+func NewFileSink(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*FileSink, error) {
+	fs := &FileSink{Path: path, MaxSize: maxSize, MaxAge: maxAge, MaxBackups: maxBackups}
+	if err := fs.open(); err != nil {
+		return nil, err
+	}
+	return fs, nil
+}
+
+// Write appends p to the current file, rotating first if the file has outgrown MaxSize or MaxAge
+func (fs *FileSink) Write(p []byte) (int, error) {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.needsRotation(len(p)) {
+		if err := fs.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := fs.file.Write(p)
+	fs.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file
+func (fs *FileSink) Close() error {
+	fs.mu.Lock()
+	defer fs.mu.Unlock()
+
+	if fs.file == nil {
+		return nil
+	}
+	return fs.file.Close()
+}
+
+func (fs *FileSink) needsRotation(nextWrite int) bool {
+	if fs.MaxSize > 0 && fs.size+int64(nextWrite) > fs.MaxSize {
+		return true
+	}
+	if fs.MaxAge > 0 && time.Since(fs.openedAt) > fs.MaxAge {
+		return true
+	}
+	return false
+}
+
+func (fs *FileSink) open() error {
+	file, err := os.OpenFile(fs.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return err
+	}
+
+	fs.file = file
+	fs.size = info.Size()
+	fs.openedAt = time.Now()
+	return nil
+}
+
+// rotate closes the current file, renames it to a timestamped backup, prunes backups beyond
+// MaxBackups and opens a fresh file at Path
+func (fs *FileSink) rotate() error {
+	if fs.file != nil {
+		if err := fs.file.Close(); err != nil {
+			return err
+		}
+	}
+
+	backup := fmt.Sprintf("%s.%s", fs.Path, time.Now().Format("20060102T150405.000000000"))
+	if err := os.Rename(fs.Path, backup); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	if err := fs.pruneBackups(); err != nil {
+		return err
+	}
+
+	return fs.open()
+}
+
+// pruneBackups removes the oldest rotated backups beyond MaxBackups, if MaxBackups is set
+func (fs *FileSink) pruneBackups() error {
+	if fs.MaxBackups <= 0 {
+		return nil
+	}
+
+	matches, err := filepath.Glob(fs.Path + ".*")
+	if err != nil {
+		return err
+	}
+
+	if len(matches) <= fs.MaxBackups {
+		return nil
+	}
+
+	sort.Strings(matches)
+
+	for _, stale := range matches[:len(matches)-fs.MaxBackups] {
+		if err := os.Remove(stale); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
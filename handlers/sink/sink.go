@@ -0,0 +1,73 @@
+// Copyright © 2017 Igor Bondarenko <igor@context7.com>
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sink provides the destinations httpr's logging handlers write to: stdout, a rotating
+// file, or a fan-out of several sinks at once.
+package sink
+
+import (
+	"io"
+	"os"
+)
+
+// Sink is a destination for log output that can be closed by Context.Close()
+type Sink interface {
+	io.Writer
+	io.Closer
+}
+
+// stdoutSink writes to os.Stdout; Close is a noop since the process owns stdout
+type stdoutSink struct{}
+
+func (stdoutSink) Write(p []byte) (int, error) { return os.Stdout.Write(p) }
+func (stdoutSink) Close() error                { return nil }
+
+// Stdout returns a Sink that writes to the standard output
+func Stdout() Sink {
+	return stdoutSink{}
+}
+
+// MultiSink fans every Write and Close out to all of its sinks
+type MultiSink struct {
+	sinks []Sink
+}
+
+// Multi returns a Sink that writes to and closes every one of sinks
+func Multi(sinks ...Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// Write writes p to every sink, stopping at and returning the first error encountered
+func (m *MultiSink) Write(p []byte) (int, error) {
+	for _, s := range m.sinks {
+		n, err := s.Write(p)
+		if err != nil {
+			return n, err
+		}
+		if n != len(p) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return len(p), nil
+}
+
+// Close closes every sink, continuing on error, and returns the first error encountered
+func (m *MultiSink) Close() error {
+	var firstErr error
+	for _, s := range m.sinks {
+		if err := s.Close(); err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	return firstErr
+}